@@ -0,0 +1,122 @@
+// Package apiclient is the frontend's typed client for the book API. It
+// wraps every call with a shared timeout, an in-memory TTL cache, and a
+// circuit breaker that falls back to the last cached response when the API
+// is down, so a slow or unavailable API server degrades the frontend
+// instead of hanging it.
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+type BookDTO struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Author  string `json:"author"`
+	Pages   string `json:"pages"`
+	Edition string `json:"edition"`
+	Year    string `json:"year"`
+}
+
+// Client is the frontend's handle onto the book API.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	cache      *ttlCache
+	breaker    *gobreaker.CircuitBreaker
+}
+
+// New builds a Client pointed at baseURL. Every request is bounded by
+// timeout; successful responses are cached for cacheTTL and served stale
+// whenever the breaker trips or a request fails.
+func New(baseURL string, timeout time.Duration, cacheTTL time.Duration) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: timeout},
+		baseURL:    baseURL,
+		cache:      newTTLCache(cacheTTL),
+		breaker: gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name: "apiclient",
+		}),
+	}
+}
+
+// BreakerState reports the circuit breaker's current state ("closed",
+// "half-open", or "open").
+func (c *Client) BreakerState() string {
+	return c.breaker.State().String()
+}
+
+// CacheHitRate is the fraction of cache lookups served from cache since
+// startup, in [0, 1].
+func (c *Client) CacheHitRate() float64 {
+	return c.cache.hitRate()
+}
+
+func (c *Client) GetBooks(ctx context.Context) ([]BookDTO, error) {
+	return fetchList[BookDTO](ctx, c, "/api/books", "books")
+}
+
+func (c *Client) GetAuthors(ctx context.Context) ([]string, error) {
+	return fetchList[string](ctx, c, "/api/authors", "authors")
+}
+
+func (c *Client) GetYears(ctx context.Context) ([]string, error) {
+	return fetchList[string](ctx, c, "/api/years", "years")
+}
+
+// fetchList runs a cached, circuit-broken GET against path and decodes the
+// JSON array response into []T. On failure it falls back to the last
+// cached value for cacheKey, if any.
+func fetchList[T any](ctx context.Context, c *Client, path string, cacheKey string) ([]T, error) {
+	if cached, found, fresh := c.cache.get(cacheKey); found && fresh {
+		c.cache.recordLookup(true)
+		return cached.([]T), nil
+	}
+
+	body, err := c.breaker.Execute(func() (interface{}, error) {
+		return c.get(ctx, path)
+	})
+	if err != nil {
+		if cached, found, _ := c.cache.get(cacheKey); found {
+			c.cache.recordLookup(true)
+			return cached.([]T), nil
+		}
+		c.cache.recordLookup(false)
+		return nil, err
+	}
+
+	var items []T
+	if err := json.Unmarshal(body.([]byte), &items); err != nil {
+		return nil, err
+	}
+
+	c.cache.recordLookup(false)
+	c.cache.set(cacheKey, items)
+	return items, nil
+}
+
+func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("apiclient: unexpected status %d from %s", resp.StatusCode, path)
+	}
+
+	return io.ReadAll(resp.Body)
+}
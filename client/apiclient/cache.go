@@ -0,0 +1,72 @@
+package apiclient
+
+import (
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// ttlCache is a small in-memory cache with a fixed TTL per entry. It also
+// tracks a running hit rate so callers can surface cache effectiveness.
+type ttlCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	hits    int64
+	lookups int64
+}
+
+func newTTLCache(ttl time.Duration) *ttlCache {
+	return &ttlCache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// get returns a cached value and whether it is still within its TTL. A
+// found-but-stale entry is still returned so callers can serve it as a
+// last resort when a refetch fails.
+func (c *ttlCache) get(key string) (value interface{}, found bool, fresh bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false, false
+	}
+
+	return entry.value, true, time.Now().Before(entry.expires)
+}
+
+func (c *ttlCache) set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = cacheEntry{value: value, expires: time.Now().Add(c.ttl)}
+}
+
+// recordLookup tallies whether a GetX call was served from cache (fresh or
+// stale-on-error) rather than a live API round trip.
+func (c *ttlCache) recordLookup(hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lookups++
+	if hit {
+		c.hits++
+	}
+}
+
+func (c *ttlCache) hitRate() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lookups == 0 {
+		return 0
+	}
+	return float64(c.hits) / float64(c.lookups)
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"time"
+
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
 	log "github.com/sirupsen/logrus"
@@ -8,10 +10,12 @@ import (
 
 type Configuration struct {
 	Api struct {
-		Url      string `env:"API_URI" envDefault:"http://server:8080"`
+		Url      string        `env:"API_URI" envDefault:"http://server:8080"`
+		Timeout  time.Duration `env:"API_TIMEOUT" envDefault:"2s"`
+		CacheTTL time.Duration `env:"API_CACHE_TTL" envDefault:"30s"`
 	}
 	Server struct {
-		Port               uint   `env:"SERVER_PORT" envDefault:"3030"`
+		Port uint `env:"SERVER_PORT" envDefault:"3030"`
 	}
 
 	Debug bool `env:"DEBUG" envDefault:"false"`
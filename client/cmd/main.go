@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"html/template"
 	"io"
@@ -9,19 +8,14 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/janthoXO/caps-ex1/client/apiclient"
 
 	log "github.com/sirupsen/logrus"
 )
 
-type BookDTO struct {
-	ID      string `json:"id"`
-	Title   string `json:"title"`
-	Author  string `json:"author"`
-	Pages   string `json:"pages"`
-	Edition string `json:"edition"`
-	Year    string `json:"year"`
-}
-
 // Wraps the "Template" struct to associate a necessary method
 // to determine the rendering procedure
 type Template struct {
@@ -56,6 +50,22 @@ func (t *Template) Render(w io.Writer, name string, data interface{}, ctx echo.C
 func main() {
 	LoadConfig()
 
+	api := apiclient.New(Cfg.Api.Url, Cfg.Api.Timeout, Cfg.Api.CacheTTL)
+
+	prometheus.MustRegister(prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{Name: "apiclient_cache_hit_rate", Help: "Fraction of apiclient lookups served from cache."},
+		api.CacheHitRate,
+	))
+	prometheus.MustRegister(prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{Name: "apiclient_breaker_open", Help: "1 if the apiclient circuit breaker is open, 0 otherwise."},
+		func() float64 {
+			if api.BreakerState() == "open" {
+				return 1
+			}
+			return 0
+		},
+	))
+
 	// Here we prepare the server
 	e := echo.New()
 
@@ -77,15 +87,7 @@ func main() {
 	})
 
 	e.GET("/books", func(c echo.Context) error {
-		resp, err := http.Get(Cfg.Api.Url + "/api/books")
-		if err != nil {
-			log.Error(err)
-			return c.NoContent(http.StatusInternalServerError)
-		}
-		defer resp.Body.Close()
-
-		var books []BookDTO
-		err = json.NewDecoder(resp.Body).Decode(&books)
+		books, err := api.GetBooks(c.Request().Context())
 		if err != nil {
 			log.Error(err)
 			return c.NoContent(http.StatusInternalServerError)
@@ -106,15 +108,7 @@ func main() {
 	})
 
 	e.GET("/authors", func(c echo.Context) error {
-		resp, err := http.Get(Cfg.Api.Url + "/api/authors")
-		if err != nil {
-			log.Error(err)
-			return c.NoContent(http.StatusInternalServerError)
-		}
-		defer resp.Body.Close()
-
-		var authors []string
-		err = json.NewDecoder(resp.Body).Decode(&authors)
+		authors, err := api.GetAuthors(c.Request().Context())
 		if err != nil {
 			log.Error(err)
 			return c.NoContent(http.StatusInternalServerError)
@@ -124,15 +118,7 @@ func main() {
 	})
 
 	e.GET("/years", func(c echo.Context) error {
-		resp, err := http.Get(Cfg.Api.Url + "/api/years")
-		if err != nil {
-			log.Error(err)
-			return c.NoContent(http.StatusInternalServerError)
-		}
-		defer resp.Body.Close()
-
-		var years []string
-		err = json.NewDecoder(resp.Body).Decode(&years)
+		years, err := api.GetYears(c.Request().Context())
 		if err != nil {
 			log.Error(err)
 			return c.NoContent(http.StatusInternalServerError)
@@ -149,6 +135,16 @@ func main() {
 		return c.NoContent(http.StatusNoContent)
 	})
 
+	e.GET("/healthz", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"status":       "ok",
+			"breaker":      api.BreakerState(),
+			"cacheHitRate": api.CacheHitRate(),
+		})
+	})
+
+	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
+
 	// We start the server and bind it to port 3030. For future references, this
 	// is the application's port and not the external one. For this first exercise,
 	// they could be the same if you use a Cloud Provider. If you use ngrok or similar,
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/go-oauth2/oauth2/v4/models"
+	"github.com/janthoXO/caps-ex1/oauth"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// runAdmin dispatches `main admin <subcommand>` invocations.
+func runAdmin(args []string) {
+	if len(args) == 0 {
+		log.Fatal("usage: admin register-client -id=<id> -secret=<secret> [-domain=<domain>]")
+	}
+
+	switch args[0] {
+	case "register-client":
+		registerClient(args[1:])
+	default:
+		log.Fatalf("unknown admin subcommand %q", args[0])
+	}
+}
+
+// registerClient provisions an OAuth2 client in oauth_clients so it can
+// authenticate against /oauth/token without reaching into Mongo by hand.
+func registerClient(args []string) {
+	fs := flag.NewFlagSet("register-client", flag.ExitOnError)
+	id := fs.String("id", "", "OAuth2 client id")
+	secret := fs.String("secret", "", "OAuth2 client secret")
+	domain := fs.String("domain", "", "OAuth2 redirect domain")
+	if err := fs.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+
+	if *id == "" || *secret == "" {
+		log.Fatal("-id and -secret are required")
+	}
+
+	LoadConfig()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(Cfg.Database.Url))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		if err := client.Disconnect(ctx); err != nil {
+			panic(err)
+		}
+	}()
+
+	clients, err := oauth.NewClientStore(ctx, client.Database(Cfg.Database.Name).Collection("oauth_clients"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := clients.Create(ctx, &models.Client{ID: *id, Secret: *secret, Domain: *domain}); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("registered client %s\n", *id)
+}
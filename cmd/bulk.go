@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/janthoXO/caps-ex1/eventlog"
+	"github.com/janthoXO/caps-ex1/oauth"
+	"github.com/janthoXO/caps-ex1/store"
+)
+
+// csvColumns is both the expected header row for imports and the header
+// written for exports, in BookDTO field order.
+var csvColumns = []string{"id", "title", "author", "pages", "edition", "year"}
+
+// bulkLineResult reports the outcome of importing a single line/row from a
+// bulk import, in the order the lines were read.
+type bulkLineResult struct {
+	Line   int    `json:"line"`
+	ID     string `json:"id,omitempty"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// parseBulkBooks reads books out of body in the given content type, one per
+// line/row, returning a book per successfully-parsed line alongside the
+// 1-based line number it came from. Lines that fail to parse are reported
+// directly in results instead of being handed to the store.
+func parseBulkBooks(contentType string, body io.Reader) (books []store.Book, lines []int, results []bulkLineResult, err error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid Content-Type: %w", err)
+	}
+
+	switch mediaType {
+	case "application/x-ndjson":
+		scanner := bufio.NewScanner(body)
+		line := 0
+		for scanner.Scan() {
+			line++
+			raw := scanner.Bytes()
+			if len(raw) == 0 {
+				continue
+			}
+
+			var dto BookDTO
+			if jsonErr := json.Unmarshal(raw, &dto); jsonErr != nil {
+				results = append(results, bulkLineResult{Line: line, Status: http.StatusBadRequest, Error: jsonErr.Error()})
+				continue
+			}
+			if dto.ID == "" || dto.Title == "" || dto.Author == "" {
+				results = append(results, bulkLineResult{Line: line, Status: http.StatusBadRequest, Error: "id, title and author are required"})
+				continue
+			}
+
+			books = append(books, fromDTO(dto))
+			lines = append(lines, line)
+		}
+		if scanErr := scanner.Err(); scanErr != nil {
+			return nil, nil, nil, scanErr
+		}
+
+	case "text/csv":
+		reader := csv.NewReader(body)
+		line := 0
+		for {
+			record, readErr := reader.Read()
+			if readErr == io.EOF {
+				break
+			}
+			line++
+			if readErr != nil {
+				results = append(results, bulkLineResult{Line: line, Status: http.StatusBadRequest, Error: readErr.Error()})
+				continue
+			}
+			if line == 1 {
+				continue // header row, symmetric with the one /api/books/export writes
+			}
+			if len(record) != len(csvColumns) {
+				results = append(results, bulkLineResult{Line: line, Status: http.StatusBadRequest, Error: fmt.Sprintf("expected %d columns, got %d", len(csvColumns), len(record))})
+				continue
+			}
+			if record[0] == "" || record[1] == "" || record[2] == "" {
+				results = append(results, bulkLineResult{Line: line, Status: http.StatusBadRequest, Error: "id, title and author are required"})
+				continue
+			}
+
+			books = append(books, store.Book{ID: record[0], Name: record[1], Author: record[2], Pages: record[3], Edition: record[4], Year: record[5]})
+			lines = append(lines, line)
+		}
+
+	default:
+		return nil, nil, nil, fmt.Errorf("unsupported Content-Type %q", mediaType)
+	}
+
+	return books, lines, results, nil
+}
+
+// handleBulkImport backs POST /api/books/bulk: it reads NDJSON or CSV from
+// the request body and inserts the parsed books via store.BulkRepository,
+// reporting one result per input line so a caller can re-import a catalog
+// idempotently (duplicates come back as 409s, not a failed batch).
+func handleBulkImport(repo store.Repository, events *eventlog.Log) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		bulkRepo, ok := repo.(store.BulkRepository)
+		if !ok {
+			return c.NoContent(http.StatusNotImplemented)
+		}
+
+		books, lines, results, err := parseBulkBooks(c.Request().Header.Get(echo.HeaderContentType), c.Request().Body)
+		if err != nil {
+			log.Error(err)
+			return c.NoContent(http.StatusBadRequest)
+		}
+
+		bulkResults, err := bulkRepo.CreateMany(c.Request().Context(), books, Cfg.Bulk.BatchSize)
+		if err != nil {
+			log.Error(err)
+			return c.NoContent(http.StatusInternalServerError)
+		}
+
+		for i, res := range bulkResults {
+			results = append(results, bulkLineResult{Line: lines[i], ID: res.ID, Status: res.Status, Error: res.Error})
+
+			if res.Status != http.StatusCreated {
+				continue
+			}
+			created := books[i]
+			if err := events.Record(c.Request().Context(), eventlog.BookCreated, created.ID, oauth.Actor(c), nil, &created); err != nil {
+				log.Error(err)
+			}
+		}
+
+		sort.Slice(results, func(i, j int) bool { return results[i].Line < results[j].Line })
+
+		return c.JSON(http.StatusOK, results)
+	}
+}
+
+// handleBulkExport backs GET /api/books/export: it streams every book out
+// of the store as NDJSON or CSV via a Mongo cursor and io.Pipe, so the
+// response body never holds the full result set in memory.
+func handleBulkExport(repo store.Repository) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		bulkRepo, ok := repo.(store.BulkRepository)
+		if !ok {
+			return c.NoContent(http.StatusNotImplemented)
+		}
+
+		format := c.QueryParam("format")
+		if format == "" {
+			format = "ndjson"
+		}
+
+		var contentType string
+		switch format {
+		case "ndjson":
+			contentType = "application/x-ndjson"
+		case "csv":
+			contentType = "text/csv"
+		default:
+			return c.NoContent(http.StatusBadRequest)
+		}
+
+		ctx := c.Request().Context()
+		pr, pw := io.Pipe()
+		// If the client goes away mid-stream, unblock streamBooks' pending
+		// write instead of leaking its goroutine and Mongo cursor forever.
+		go func() {
+			<-ctx.Done()
+			pr.CloseWithError(ctx.Err())
+		}()
+		go func() {
+			pw.CloseWithError(streamBooks(ctx, bulkRepo, format, pw))
+		}()
+
+		return c.Stream(http.StatusOK, contentType, pr)
+	}
+}
+
+// streamBooks walks bulkRepo with StreamAll and writes each book to w as it
+// arrives, in the given format, without buffering the full result set.
+func streamBooks(ctx context.Context, bulkRepo store.BulkRepository, format string, w io.Writer) error {
+	if format == "csv" {
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvColumns); err != nil {
+			return err
+		}
+
+		err := bulkRepo.StreamAll(ctx, func(book store.Book) error {
+			return writer.Write([]string{book.ID, book.Name, book.Author, book.Pages, book.Edition, book.Year})
+		})
+		writer.Flush()
+		if err != nil {
+			return err
+		}
+		return writer.Error()
+	}
+
+	enc := json.NewEncoder(w)
+	return bulkRepo.StreamAll(ctx, func(book store.Book) error {
+		return enc.Encode(toDTO(book))
+	})
+}
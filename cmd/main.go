@@ -6,32 +6,24 @@ import (
 	"html/template"
 	"io"
 	"net/http"
-	"slices"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/janthoXO/caps-ex1/eventlog"
+	"github.com/janthoXO/caps-ex1/oauth"
+	"github.com/janthoXO/caps-ex1/store"
+	_ "github.com/janthoXO/caps-ex1/store/memstore"
+	_ "github.com/janthoXO/caps-ex1/store/mongostore"
+
 	log "github.com/sirupsen/logrus"
 )
 
-// Defines a "model" that we can use to communicate with the
-// frontend or the database
-// More on these "tags" like `bson:"_id,omitempty"`: https://go.dev/wiki/Well-known-struct-tags
-type BookStore struct {
-	MongoID     primitive.ObjectID `bson:"_id,omitempty"`
-	ID          string             `bson:"id,omitempty"`
-	BookName    string             `bson:"bookname,omitempty"`
-	BookAuthor  string             `bson:"bookauthor,omitempty"`
-	BookEdition string             `bson:"bookedition,omitempty"`
-	BookPages   string             `bson:"bookpages,omitempty"`
-	BookYear    string             `bson:"bookyear,omitempty"`
-}
-
 type BookDTO struct {
 	ID      string `json:"id"`
 	Title   string `json:"title"`
@@ -41,24 +33,26 @@ type BookDTO struct {
 	Year    string `json:"year"`
 }
 
-func (b BookStore) ToDTO() BookDTO {
+func toDTO(b store.Book) BookDTO {
 	return BookDTO{
 		ID:      b.ID,
-		Title:   b.BookName,
-		Author:  b.BookAuthor,
-		Pages:   b.BookPages,
-		Edition: b.BookEdition,
-		Year:    b.BookYear,
+		Title:   b.Name,
+		Author:  b.Author,
+		Pages:   b.Pages,
+		Edition: b.Edition,
+		Year:    b.Year,
 	}
 }
 
-func (b *BookStore) FromDTO(dto BookDTO) {
-	b.ID = dto.ID
-	b.BookName = dto.Title
-	b.BookAuthor = dto.Author
-	b.BookEdition = dto.Edition
-	b.BookPages = dto.Pages
-	b.BookYear = dto.Year
+func fromDTO(dto BookDTO) store.Book {
+	return store.Book{
+		ID:      dto.ID,
+		Name:    dto.Title,
+		Author:  dto.Author,
+		Edition: dto.Edition,
+		Pages:   dto.Pages,
+		Year:    dto.Year,
+	}
 }
 
 // Wraps the "Template" struct to associate a necessary method
@@ -92,210 +86,66 @@ func (t *Template) Render(w io.Writer, name string, data interface{}, ctx echo.C
 	return t.tmpl.ExecuteTemplate(w, name, data)
 }
 
-// Here we make sure the connection to the database is correct and initial
-// configurations exists. Otherwise, we create the proper database and collection
-// we will store the data.
-// To ensure correct management of the collection, we create a return a
-// reference to the collection to always be used. Make sure if you create other
-// files, that you pass the proper value to ensure communication with the
-// database
-// More on what bson means: https://www.mongodb.com/docs/drivers/go/current/fundamentals/bson/
-func prepareDatabase(client *mongo.Client, dbName string, collecName string) (*mongo.Collection, error) {
-	db := client.Database(dbName)
-
-	names, err := db.ListCollectionNames(context.TODO(), bson.D{{}})
-	if err != nil {
-		return nil, err
-	}
-
-	log.Debugf("Collections in database %s: %v", dbName, names)
-	if !slices.Contains(names, collecName) {
-		cmd := bson.D{{"create", collecName}}
-		var result bson.M
-		if err = db.RunCommand(context.TODO(), cmd).Decode(&result); err != nil {
-			log.Fatal(err)
-			return nil, err
-		}
-	}
-
-	coll := db.Collection(collecName)
-
-	// Create a unique index on the "id" field
-	_, err = coll.Indexes().CreateOne(
-		context.TODO(),
-		mongo.IndexModel{
-			Keys:    bson.D{{Key: "id", Value: 1}},
-			Options: options.Index().SetUnique(true),
-		},
-	)
-	if err != nil {
-		log.Fatal(err)
-		return nil, err
-	}
-
-	// Create a composite unique index on name, author, year and pages
-    _, err = coll.Indexes().CreateOne(
-        context.TODO(),
-        mongo.IndexModel{
-            Keys: bson.D{
-                {Key: "bookname", Value: 1},
-                {Key: "bookauthor", Value: 1},
-                {Key: "bookyear", Value: 1},
-                {Key: "bookpages", Value: 1},
-            },
-            Options: options.Index().SetUnique(true),
-        },
-    )
-    if err != nil {
-        log.Fatal(err)
-        return nil, err
-    }
-
-	return coll, nil
-}
-
-// Here we prepare some fictional data and we insert it into the database
+// Here we prepare some fictional data and we insert it into the repository
 // the first time we connect to it. Otherwise, we check if it already exists.
-func prepareData(client *mongo.Client, coll *mongo.Collection) {
-	startData := []BookStore{
+func prepareData(ctx context.Context, repo store.Repository) {
+	startData := []store.Book{
 		{
-			ID:          "example1",
-			BookName:    "The Vortex",
-			BookAuthor:  "JosÃ© Eustasio Rivera",
-			BookEdition: "958-30-0804-4",
-			BookPages:   "292",
-			BookYear:    "1924",
+			ID:      "example1",
+			Name:    "The Vortex",
+			Author:  "JosÃ© Eustasio Rivera",
+			Edition: "958-30-0804-4",
+			Pages:   "292",
+			Year:    "1924",
 		},
 		{
-			ID:          "example2",
-			BookName:    "Frankenstein",
-			BookAuthor:  "Mary Shelley",
-			BookEdition: "978-3-649-64609-9",
-			BookPages:   "280",
-			BookYear:    "1818",
+			ID:      "example2",
+			Name:    "Frankenstein",
+			Author:  "Mary Shelley",
+			Edition: "978-3-649-64609-9",
+			Pages:   "280",
+			Year:    "1818",
 		},
 		{
-			ID:          "example3",
-			BookName:    "The Black Cat",
-			BookAuthor:  "Edgar Allan Poe",
-			BookEdition: "978-3-99168-238-7",
-			BookPages:   "280",
-			BookYear:    "1843",
+			ID:      "example3",
+			Name:    "The Black Cat",
+			Author:  "Edgar Allan Poe",
+			Edition: "978-3-99168-238-7",
+			Pages:   "280",
+			Year:    "1843",
 		},
 	}
 
-	// This syntax helps us iterate over arrays. It behaves similar to Python
-	// However, range always returns a tuple: (idx, elem). You can ignore the idx
-	// by using _.
-	// In the topic of function returns: sadly, there is no standard on return types from function. Most functions
-	// return a tuple with (res, err), but this is not granted. Some functions
-	// might return a ret value that includes res and the err, others might have
-	// an out parameter.
-	for _, book := range startData {
-		cursor, err := coll.Find(context.TODO(), book)
-		var results []BookStore
-		if err = cursor.All(context.TODO(), &results); err != nil {
-			panic(err)
-		}
-		if len(results) > 1 {
-			log.Fatal("more records were found")
-		} else if len(results) == 0 {
-			result, err := coll.InsertOne(context.TODO(), book)
-			if err != nil {
-				panic(err)
-			} else {
-				fmt.Printf("%+v\n", result)
-			}
-
-		} else {
-			for _, res := range results {
-				cursor.Decode(&res)
-				fmt.Printf("%+v\n", res)
-			}
-		}
-	}
-}
-
-func insertBook(coll *mongo.Collection, book BookStore) (BookStore, error) {
-	result, err := coll.InsertOne(context.TODO(), book)
-	if err != nil {
-		return BookStore{}, err
-	}
-
-	book.MongoID = result.InsertedID.(primitive.ObjectID)
-	return book, nil
-}
-
-// Generic method to perform "SELECT * FROM BOOKS" (if this was SQL, which
-// it is not :D ), and then we convert it into an array of map. In Golang, you
-// define a map by writing map[<key type>]<value type>{<key>:<value>}.
-// interface{} is a special type in Golang, basically a wildcard...
-func findAllBooks(coll *mongo.Collection) ([]BookStore, error) {
-	cursor, err := coll.Find(context.TODO(), bson.D{{}})
-	if err != nil {
-		return nil, err
-	}
-
-	var results []BookStore
-	err = cursor.All(context.TODO(), &results)
-	if err != nil {
-		return nil, err
-	}
-
-	return results, nil
-}
-
-func updateBook(coll *mongo.Collection, id string, book BookStore) (BookStore, error) {
-	filter := bson.M{"id": id}
-	update := bson.M{"$set": book}
-
-	result, err := coll.UpdateOne(context.TODO(), filter, update)
+	existing, err := repo.GetAll(ctx)
 	if err != nil {
-		return BookStore{}, err
-	}
-
-	log.Debugf("result: %+v", result)
-	if result.ModifiedCount == 0 && result.MatchedCount == 0 {
-		return BookStore{}, fmt.Errorf("no book found with id %s", id)
+		panic(err)
 	}
 
-	return book, nil
-}
-
-func deleteBook(coll *mongo.Collection, id string) error {
-	_, err := coll.DeleteOne(context.TODO(), bson.M{"id": id})
-	return err
-}
-
-func findAllAuthors(coll *mongo.Collection) (authors []string, err error) {
-	var results []interface{}
-	results, err = coll.Distinct(context.TODO(), "bookauthor", bson.D{{}})
-	if err != nil {
-		return nil, err
+	haveID := make(map[string]bool, len(existing))
+	for _, book := range existing {
+		haveID[book.ID] = true
 	}
 
-	for _, res := range results {
-		authors = append(authors, res.(string))
+	for _, book := range startData {
+		if haveID[book.ID] {
+			continue
+		}
+		result, err := repo.Create(ctx, book)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("%+v\n", result)
 	}
-
-	return authors, err
 }
 
-func findAllYears(coll *mongo.Collection) (years []string, err error) {
-	var results []interface{}
-	results, err = coll.Distinct(context.TODO(), "bookyear", bson.D{{}})
-	if err != nil {
-		return nil, err
-	}
-
-	for _, res := range results {
-		years = append(years, res.(string))
+func main() {
+	// `main admin <subcommand>` manages OAuth2 clients out of band from the
+	// HTTP server; see admin.go.
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		runAdmin(os.Args[2:])
+		return
 	}
 
-	return years, err
-}
-
-func main() {
 	// Connect to the database. Such defer keywords are used once the local
 	// context returns; for this case, the local context is the main function
 	// By user defer function, we make sure we don't leave connections
@@ -305,24 +155,51 @@ func main() {
 
 	// Get MongoDB URI from environment variable or use default
 	LoadConfig()
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(Cfg.Database.Url))
 
-	// This is another way to specify the call of a function. You can define inline
-	// functions (or anonymous functions, similar to the behavior in Python)
+	// The storage backend is selected via STORAGE_BACKEND (e.g. "mongo" or
+	// "memory"); handlers below only ever see the store.Repository
+	// interface, so adding a new backend never touches them.
+	repo, err := store.New(Cfg.Storage.Backend, store.Config{
+		DatabaseURL:  Cfg.Database.Url,
+		DatabaseName: Cfg.Database.Name,
+		Collection:   "information",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if closer, ok := repo.(interface{ Close(context.Context) error }); ok {
+		defer func() {
+			if err := closer.Close(ctx); err != nil {
+				panic(err)
+			}
+		}()
+	}
+
+	prepareData(ctx, repo)
+
+	// The audit trail and the OAuth2 client/token stores always live in
+	// Mongo, independent of which store.Repository backend is serving book
+	// reads and writes.
+	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(Cfg.Database.Url))
+	if err != nil {
+		log.Fatal(err)
+	}
 	defer func() {
-		if err = client.Disconnect(ctx); err != nil {
+		if err := mongoClient.Disconnect(ctx); err != nil {
 			panic(err)
 		}
 	}()
 
-	// You can use such name for the database and collection, or come up with
-	// one by yourself!
-	coll, err := prepareDatabase(client, Cfg.Database.Name, "information")
+	events, err := eventlog.New(ctx, mongoClient, Cfg.Database.Name)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	prepareData(client, coll)
+	oauthSrv, err := oauth.NewServer(ctx, mongoClient.Database(Cfg.Database.Name))
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Here we prepare the server
 	e := echo.New()
@@ -345,7 +222,7 @@ func main() {
 	})
 
 	e.GET("/books", func(c echo.Context) error {
-		books, err := findAllBooks(coll)
+		books, err := repo.GetAll(c.Request().Context())
 		if err != nil {
 			log.Error(err)
 			return c.NoContent(http.StatusInternalServerError)
@@ -354,11 +231,11 @@ func main() {
 		var ret []map[string]interface{}
 		for _, res := range books {
 			ret = append(ret, map[string]interface{}{
-				"ID":          res.MongoID.Hex(),
-				"BookName":    res.BookName,
-				"BookAuthor":  res.BookAuthor,
-				"BookEdition": res.BookEdition,
-				"BookPages":   res.BookPages,
+				"ID":          res.ID,
+				"BookName":    res.Name,
+				"BookAuthor":  res.Author,
+				"BookEdition": res.Edition,
+				"BookPages":   res.Pages,
 			})
 		}
 
@@ -366,7 +243,7 @@ func main() {
 	})
 
 	e.GET("/authors", func(c echo.Context) error {
-		authors, err := findAllAuthors(coll)
+		authors, err := repo.DistinctAuthors(c.Request().Context())
 		if err != nil {
 			log.Error(err)
 			return c.NoContent(http.StatusInternalServerError)
@@ -376,7 +253,7 @@ func main() {
 	})
 
 	e.GET("/years", func(c echo.Context) error {
-		years, err := findAllYears(coll)
+		years, err := repo.DistinctYears(c.Request().Context())
 		if err != nil {
 			log.Error(err)
 			return c.NoContent(http.StatusInternalServerError)
@@ -389,10 +266,95 @@ func main() {
 		return c.Render(200, "search-bar", nil)
 	})
 
+	// Backs the search page: the search-bar form submits its query here as a
+	// GET request and the response is swapped directly into the book-table
+	// view.
+	e.GET("/search/results", func(c echo.Context) error {
+		page, err := strconv.Atoi(c.QueryParam("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+		pageSize, err := strconv.Atoi(c.QueryParam("pageSize"))
+		if err != nil || pageSize < 1 {
+			pageSize = 10
+		}
+
+		filters := store.SearchFilters{
+			Author: c.QueryParam("author"),
+			Year:   c.QueryParam("year"),
+			Pages:  c.QueryParam("pages"),
+		}
+
+		books, _, err := repo.Search(c.Request().Context(), c.QueryParam("q"), filters, page, pageSize)
+		if err != nil {
+			log.Error(err)
+			return c.NoContent(http.StatusInternalServerError)
+		}
+
+		var ret []map[string]interface{}
+		for _, res := range books {
+			ret = append(ret, map[string]interface{}{
+				"ID":          res.ID,
+				"BookName":    res.Name,
+				"BookAuthor":  res.Author,
+				"BookEdition": res.Edition,
+				"BookPages":   res.Pages,
+			})
+		}
+
+		return c.Render(200, "book-table", ret)
+	})
+
 	e.GET("/create", func(c echo.Context) error {
 		return c.NoContent(http.StatusNoContent)
 	})
 
+	e.GET("/oauth/authorize", oauthSrv.HandleAuthorize)
+	e.POST("/oauth/authorize", oauthSrv.HandleAuthorize)
+	e.POST("/oauth/token", oauthSrv.HandleToken)
+
+	e.GET("/events", func(c echo.Context) error {
+		return c.Render(200, "events-timeline", nil)
+	})
+
+	e.GET("/api/books/:id/events", func(c echo.Context) error {
+		id := c.Param("id")
+		if id == "" {
+			log.Error("Missing ID")
+			return c.NoContent(http.StatusBadRequest)
+		}
+
+		bookEvents, err := events.ListByBook(c.Request().Context(), id)
+		if err != nil {
+			log.Error(err)
+			return c.NoContent(http.StatusInternalServerError)
+		}
+
+		return c.JSON(http.StatusOK, bookEvents)
+	})
+
+	e.GET("/api/events", func(c echo.Context) error {
+		var since time.Time
+		if raw := c.QueryParam("since"); raw != "" {
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				log.Error(err)
+				return c.NoContent(http.StatusBadRequest)
+			}
+			since = parsed
+		}
+
+		eventType := eventlog.EventType(c.QueryParam("type"))
+
+		allEvents, err := events.ListSince(c.Request().Context(), since, eventType)
+		if err != nil {
+			log.Error(err)
+			return c.NoContent(http.StatusInternalServerError)
+		}
+
+		return c.JSON(http.StatusOK, allEvents)
+	})
+
 	// You will have to expand on the allowed methods for the path
 	// `/api/route`, following the common standard.
 	// A very good documentation is found here:
@@ -400,7 +362,7 @@ func main() {
 	// It specifies the expected returned codes for each type of request
 	// method.
 	e.GET("/api/books", func(c echo.Context) error {
-		books, err := findAllBooks(coll)
+		books, err := repo.GetAll(c.Request().Context())
 		if err != nil {
 			log.Error(err)
 			return c.NoContent(http.StatusInternalServerError)
@@ -408,12 +370,46 @@ func main() {
 
 		var ret []BookDTO
 		for _, book := range books {
-			bookDTO := book.ToDTO()
-			ret = append(ret, bookDTO)
+			ret = append(ret, toDTO(book))
 		}
 		return c.JSON(http.StatusOK, ret)
 	})
 
+	e.GET("/api/books/search", func(c echo.Context) error {
+		page, err := strconv.Atoi(c.QueryParam("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+		pageSize, err := strconv.Atoi(c.QueryParam("pageSize"))
+		if err != nil || pageSize < 1 {
+			pageSize = 10
+		}
+
+		filters := store.SearchFilters{
+			Author: c.QueryParam("author"),
+			Year:   c.QueryParam("year"),
+			Pages:  c.QueryParam("pages"),
+		}
+
+		books, total, err := repo.Search(c.Request().Context(), c.QueryParam("q"), filters, page, pageSize)
+		if err != nil {
+			log.Error(err)
+			return c.NoContent(http.StatusInternalServerError)
+		}
+
+		var ret []BookDTO
+		for _, book := range books {
+			ret = append(ret, toDTO(book))
+		}
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"results":  ret,
+			"total":    total,
+			"page":     page,
+			"pageSize": pageSize,
+		})
+	})
+
 	e.POST("/api/books", func(c echo.Context) error {
 		var err error
 		book := BookDTO{}
@@ -426,20 +422,18 @@ func main() {
 			return c.NoContent(http.StatusBadRequest)
 		}
 
-		// Convert DTO to BookStore
-		bookStore := BookStore{}
-		bookStore.FromDTO(book)
-
-		// Insert the book into the database
-		bookStore, err = insertBook(coll, bookStore)
+		created, err := repo.Create(c.Request().Context(), fromDTO(book))
 		if err != nil {
 			log.Error(err)
 			return c.NoContent(http.StatusInternalServerError)
 		}
 
-		BookDTO := bookStore.ToDTO()
-		return c.JSON(http.StatusCreated, BookDTO)
-	})
+		if err := events.Record(c.Request().Context(), eventlog.BookCreated, created.ID, oauth.Actor(c), nil, &created); err != nil {
+			log.Error(err)
+		}
+
+		return c.JSON(http.StatusCreated, toDTO(created))
+	}, oauthSrv.RequireScope("books:write"))
 
 	e.PUT("/api/books/:id", func(c echo.Context) error {
 		id := c.Param("id")
@@ -454,17 +448,25 @@ func main() {
 			return c.NoContent(http.StatusBadRequest)
 		}
 		book.ID = id
-		bookStore := BookStore{}
-		bookStore.FromDTO(book)
 
-		bookStore, err = updateBook(coll, id, bookStore)
+		before, err := repo.Get(c.Request().Context(), id)
+		var beforePtr *store.Book
+		if err == nil {
+			beforePtr = &before
+		}
+
+		updated, err := repo.Update(c.Request().Context(), id, fromDTO(book))
 		if err != nil {
 			log.Error(err)
 			return c.NoContent(http.StatusInternalServerError)
 		}
 
+		if err := events.Record(c.Request().Context(), eventlog.BookUpdated, id, oauth.Actor(c), beforePtr, &updated); err != nil {
+			log.Error(err)
+		}
+
 		return c.NoContent(http.StatusOK)
-	})
+	}, oauthSrv.RequireScope("books:write"))
 
 	e.DELETE("/api/books/:id", func(c echo.Context) error {
 		id := c.Param("id")
@@ -472,14 +474,29 @@ func main() {
 			log.Error("Missing ID")
 			return c.NoContent(http.StatusBadRequest)
 		}
-		err := deleteBook(coll, id)
+		before, beforeErr := repo.Get(c.Request().Context(), id)
+
+		err := repo.Delete(c.Request().Context(), id)
 		if err != nil {
 			log.Error(err)
 			return c.NoContent(http.StatusInternalServerError)
 		}
 
+		// repo.Delete succeeds whether or not id matched anything, so only
+		// record the event when we know from the Get above that a book was
+		// actually there to delete - otherwise this writes a phantom
+		// book.deleted event for an id that never existed.
+		if beforeErr == nil {
+			if err := events.Record(c.Request().Context(), eventlog.BookDeleted, id, oauth.Actor(c), &before, nil); err != nil {
+				log.Error(err)
+			}
+		}
+
 		return c.NoContent(http.StatusOK)
-	})
+	}, oauthSrv.RequireScope("books:write"))
+
+	e.POST("/api/books/bulk", handleBulkImport(repo, events), oauthSrv.RequireScope("books:write"))
+	e.GET("/api/books/export", handleBulkExport(repo))
 
 	// We start the server and bind it to port 3030. For future references, this
 	// is the application's port and not the external one. For this first exercise,
@@ -0,0 +1,113 @@
+// Package eventlog records an audit trail of book mutations into a
+// dedicated "bookevents" Mongo collection, independent of which
+// store.Repository backend is actually serving reads and writes.
+package eventlog
+
+import (
+	"context"
+	"time"
+
+	"github.com/janthoXO/caps-ex1/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func optionsSortByTimestamp() *options.FindOptions {
+	return options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}})
+}
+
+// EventType identifies what kind of change a book went through.
+type EventType string
+
+const (
+	BookCreated EventType = "book.created"
+	BookUpdated EventType = "book.updated"
+	BookDeleted EventType = "book.deleted"
+)
+
+// Event is one audit record. Before/After are nullable pointers so partial
+// updates (or the absence of a prior/resulting state on create/delete)
+// still produce a valid diff.
+type Event struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	EventType EventType          `bson:"event_type" json:"event_type"`
+	BookID    string             `bson:"book_id" json:"book_id"`
+	Timestamp time.Time          `bson:"timestamp" json:"timestamp"`
+	Actor     string             `bson:"actor,omitempty" json:"actor,omitempty"`
+	Before    *store.Book        `bson:"before,omitempty" json:"before,omitempty"`
+	After     *store.Book        `bson:"after,omitempty" json:"after,omitempty"`
+}
+
+// Log appends to and queries the bookevents collection.
+type Log struct {
+	coll *mongo.Collection
+}
+
+// New prepares the bookevents collection and its indexes.
+func New(ctx context.Context, client *mongo.Client, dbName string) (*Log, error) {
+	coll := client.Database(dbName).Collection("bookevents")
+
+	_, err := coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "book_id", Value: 1}}},
+		{Keys: bson.D{{Key: "timestamp", Value: 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Log{coll: coll}, nil
+}
+
+// Record appends an audit event. actor may be empty when the caller isn't
+// identified (see oauth.Actor, which is empty off RequireScope routes).
+func (l *Log) Record(ctx context.Context, eventType EventType, bookID string, actor string, before *store.Book, after *store.Book) error {
+	event := Event{
+		EventType: eventType,
+		BookID:    bookID,
+		Timestamp: time.Now(),
+		Actor:     actor,
+		Before:    before,
+		After:     after,
+	}
+
+	_, err := l.coll.InsertOne(ctx, event)
+	return err
+}
+
+// ListByBook returns every event recorded for a given book, oldest first.
+func (l *Log) ListByBook(ctx context.Context, bookID string) ([]Event, error) {
+	cursor, err := l.coll.Find(ctx, bson.M{"book_id": bookID}, optionsSortByTimestamp())
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// ListSince returns every event at or after since, optionally narrowed to a
+// single event type, oldest first.
+func (l *Log) ListSince(ctx context.Context, since time.Time, eventType EventType) ([]Event, error) {
+	filter := bson.M{"timestamp": bson.M{"$gte": since}}
+	if eventType != "" {
+		filter["event_type"] = eventType
+	}
+
+	cursor, err := l.coll.Find(ctx, filter, optionsSortByTimestamp())
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,54 @@
+// Package oauth wires github.com/go-oauth2/oauth2 into the server, backing
+// both its client registry and its issued tokens with the existing Mongo
+// connection (collections "oauth_clients" and "oauth_tokens").
+package oauth
+
+import (
+	"context"
+
+	oauth2 "github.com/go-oauth2/oauth2/v4"
+	"github.com/go-oauth2/oauth2/v4/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ClientStore implements oauth2.ClientStore on top of the oauth_clients
+// collection.
+type ClientStore struct {
+	coll *mongo.Collection
+}
+
+// NewClientStore prepares the oauth_clients collection and its id index.
+func NewClientStore(ctx context.Context, coll *mongo.Collection) (*ClientStore, error) {
+	_, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClientStore{coll: coll}, nil
+}
+
+// GetByID satisfies oauth2.ClientStore.
+func (s *ClientStore) GetByID(ctx context.Context, id string) (oauth2.ClientInfo, error) {
+	var client models.Client
+	err := s.coll.FindOne(ctx, bson.M{"id": id}).Decode(&client)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// Create registers a new client. It is used by the `admin register-client`
+// CLI subcommand rather than exposed over HTTP.
+func (s *ClientStore) Create(ctx context.Context, client *models.Client) error {
+	_, err := s.coll.InsertOne(ctx, client)
+	return err
+}
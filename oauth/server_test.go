@@ -0,0 +1,138 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-oauth2/oauth2/v4/generates"
+	"github.com/go-oauth2/oauth2/v4/manage"
+	"github.com/go-oauth2/oauth2/v4/models"
+	oauthserver "github.com/go-oauth2/oauth2/v4/server"
+	oauthstore "github.com/go-oauth2/oauth2/v4/store"
+	"github.com/labstack/echo/v4"
+)
+
+// newTestServer wires a Server against go-oauth2's in-memory client/token
+// stores instead of Mongo, and registers an access token for clientID
+// carrying scope, so RequireScope can be exercised without a database.
+func newTestServer(t *testing.T, clientID string, scope string) (*Server, string) {
+	t.Helper()
+
+	manager := manage.NewDefaultManager()
+	manager.MapClientStorage(oauthstore.NewClientStore())
+	manager.MapAccessGenerate(generates.NewAccessGenerate())
+
+	tokenStore, err := oauthstore.NewMemoryTokenStore()
+	if err != nil {
+		t.Fatalf("NewMemoryTokenStore: %v", err)
+	}
+	manager.MapTokenStorage(tokenStore)
+
+	const access = "test-access-token"
+	token := models.NewToken()
+	token.SetClientID(clientID)
+	token.SetScope(scope)
+	token.SetAccess(access)
+	// The buntdb-backed memory store expires a key immediately if it's
+	// marked expiring with a zero TTL, so a non-zero AccessExpiresIn is
+	// required for the token to actually be retrievable afterward; without
+	// AccessCreateAt too, Manager.LoadAccessToken treats it as already
+	// expired relative to the zero time.
+	token.SetAccessCreateAt(time.Now())
+	token.SetAccessExpiresIn(time.Hour)
+	if err := tokenStore.Create(context.Background(), token); err != nil {
+		t.Fatalf("tokenStore.Create: %v", err)
+	}
+
+	srv := oauthserver.NewDefaultServer(manager)
+	srv.SetClientInfoHandler(oauthserver.ClientFormHandler)
+
+	return &Server{srv: srv}, access
+}
+
+func contextWithBearer(access string) echo.Context {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if access != "" {
+		req.Header.Set("Authorization", "Bearer "+access)
+	}
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func TestRequireScopeRejectsMissingToken(t *testing.T) {
+	s, _ := newTestServer(t, "client1", "books:write")
+	c := contextWithBearer("")
+
+	err := s.RequireScope("books:write")(func(c echo.Context) error {
+		t.Fatal("next handler should not run without a token")
+		return nil
+	})(c)
+	if err != nil {
+		t.Fatalf("handler: %v", err)
+	}
+
+	rec := c.Response().Writer.(*httptest.ResponseRecorder)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestRequireScopeRejectsMissingScope(t *testing.T) {
+	s, access := newTestServer(t, "client1", "books:read")
+	c := contextWithBearer(access)
+
+	err := s.RequireScope("books:write")(func(c echo.Context) error {
+		t.Fatal("next handler should not run without the required scope")
+		return nil
+	})(c)
+	if err != nil {
+		t.Fatalf("handler: %v", err)
+	}
+
+	rec := c.Response().Writer.(*httptest.ResponseRecorder)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestRequireScopeAllowsMatchingScopeAndSetsActor(t *testing.T) {
+	s, access := newTestServer(t, "client1", "books:read books:write")
+	c := contextWithBearer(access)
+
+	var gotActor string
+	err := s.RequireScope("books:write")(func(c echo.Context) error {
+		gotActor = Actor(c)
+		return c.NoContent(http.StatusOK)
+	})(c)
+	if err != nil {
+		t.Fatalf("handler: %v", err)
+	}
+
+	rec := c.Response().Writer.(*httptest.ResponseRecorder)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if gotActor != "client1" {
+		t.Fatalf("got actor %q, want %q", gotActor, "client1")
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	cases := []struct {
+		granted string
+		want    string
+		ok      bool
+	}{
+		{"books:read books:write", "books:write", true},
+		{"books:read", "books:write", false},
+		{"", "books:write", false},
+	}
+
+	for _, tc := range cases {
+		if got := hasScope(tc.granted, tc.want); got != tc.ok {
+			t.Errorf("hasScope(%q, %q) = %v, want %v", tc.granted, tc.want, got, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package oauth
+
+import (
+	"context"
+
+	oauth2 "github.com/go-oauth2/oauth2/v4"
+	"github.com/go-oauth2/oauth2/v4/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TokenStore implements oauth2.TokenStore on top of the oauth_tokens
+// collection, storing models.Token documents as-is since that type already
+// carries its own bson tags.
+type TokenStore struct {
+	coll *mongo.Collection
+}
+
+// NewTokenStore prepares the oauth_tokens collection.
+func NewTokenStore(ctx context.Context, coll *mongo.Collection) (*TokenStore, error) {
+	_, err := coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "Code", Value: 1}}},
+		{Keys: bson.D{{Key: "Access", Value: 1}}},
+		{Keys: bson.D{{Key: "Refresh", Value: 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenStore{coll: coll}, nil
+}
+
+// Create satisfies oauth2.TokenStore.
+func (s *TokenStore) Create(ctx context.Context, info oauth2.TokenInfo) error {
+	_, err := s.coll.InsertOne(ctx, info)
+	return err
+}
+
+func (s *TokenStore) RemoveByCode(ctx context.Context, code string) error {
+	_, err := s.coll.DeleteOne(ctx, bson.M{"Code": code})
+	return err
+}
+
+func (s *TokenStore) RemoveByAccess(ctx context.Context, access string) error {
+	_, err := s.coll.DeleteOne(ctx, bson.M{"Access": access})
+	return err
+}
+
+func (s *TokenStore) RemoveByRefresh(ctx context.Context, refresh string) error {
+	_, err := s.coll.DeleteOne(ctx, bson.M{"Refresh": refresh})
+	return err
+}
+
+func (s *TokenStore) GetByCode(ctx context.Context, code string) (oauth2.TokenInfo, error) {
+	return s.findOneBy(ctx, bson.M{"Code": code})
+}
+
+func (s *TokenStore) GetByAccess(ctx context.Context, access string) (oauth2.TokenInfo, error) {
+	return s.findOneBy(ctx, bson.M{"Access": access})
+}
+
+func (s *TokenStore) GetByRefresh(ctx context.Context, refresh string) (oauth2.TokenInfo, error) {
+	return s.findOneBy(ctx, bson.M{"Refresh": refresh})
+}
+
+func (s *TokenStore) findOneBy(ctx context.Context, filter bson.M) (oauth2.TokenInfo, error) {
+	token := models.NewToken()
+	err := s.coll.FindOne(ctx, filter).Decode(token)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
@@ -8,11 +8,17 @@ import (
 
 type Configuration struct {
 	Database struct {
-		Url      string `env:"DATABASE_URI,notEmpty"`
-		Name 	string `env:"DB_NAME" envDefault:"exercise-3"`
+		Url  string `env:"DATABASE_URI,notEmpty"`
+		Name string `env:"DB_NAME" envDefault:"exercise-3"`
+	}
+	Storage struct {
+		Backend string `env:"STORAGE_BACKEND" envDefault:"mongo"`
 	}
 	Server struct {
-		Port               uint   `env:"SERVER_PORT" envDefault:"8080"`
+		Port uint `env:"SERVER_PORT" envDefault:"8080"`
+	}
+	Bulk struct {
+		BatchSize int `env:"BULK_BATCH_SIZE" envDefault:"100"`
 	}
 
 	Debug bool `env:"DEBUG" envDefault:"false"`
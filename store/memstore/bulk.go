@@ -0,0 +1,48 @@
+package memstore
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/janthoXO/caps-ex1/store"
+)
+
+// CreateMany satisfies store.BulkRepository. It mirrors the id-uniqueness
+// rule enforced by Create, reporting a duplicate as a 409 for that book
+// instead of aborting the rest of the batch. batchSize is accepted for
+// interface parity with mongostore but has no effect here since there is no
+// round-trip cost to batch against.
+func (s *Store) CreateMany(ctx context.Context, books []store.Book, batchSize int) ([]store.BulkResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := make([]store.BulkResult, len(books))
+	for i, book := range books {
+		if _, ok := s.books[book.ID]; ok {
+			results[i] = store.BulkResult{ID: book.ID, Status: http.StatusConflict, Error: "duplicate book"}
+			continue
+		}
+
+		s.books[book.ID] = book
+		results[i] = store.BulkResult{ID: book.ID, Status: http.StatusCreated}
+	}
+
+	return results, nil
+}
+
+// StreamAll satisfies store.BulkRepository. The in-memory backend has
+// nothing to stream from, so it just walks GetAll's snapshot.
+func (s *Store) StreamAll(ctx context.Context, fn func(store.Book) error) error {
+	books, err := s.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, book := range books {
+		if err := fn(book); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+// Package memstore implements store.Repository in memory, with no external
+// dependencies. It is meant for tests and for local development without a
+// running MongoDB. Importing the package registers it under the name
+// "memory".
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/janthoXO/caps-ex1/store"
+)
+
+func init() {
+	store.Register("memory", New)
+}
+
+// Store is a store.Repository backed by a map guarded by a mutex.
+type Store struct {
+	mu    sync.RWMutex
+	books map[string]store.Book
+}
+
+// New returns an empty in-memory Store. cfg is ignored; it satisfies
+// store.Factory and is registered as "memory".
+func New(cfg store.Config) (store.Repository, error) {
+	return &Store{books: make(map[string]store.Book)}, nil
+}
+
+func (s *Store) Create(ctx context.Context, book store.Book) (store.Book, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.books[book.ID]; ok {
+		return store.Book{}, fmt.Errorf("book with id %s already exists", book.ID)
+	}
+
+	s.books[book.ID] = book
+	return book, nil
+}
+
+func (s *Store) Update(ctx context.Context, id string, book store.Book) (store.Book, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.books[id]; !ok {
+		return store.Book{}, store.ErrNotFound
+	}
+
+	book.ID = id
+	s.books[id] = book
+	return book, nil
+}
+
+func (s *Store) Get(ctx context.Context, id string) (store.Book, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	book, ok := s.books[id]
+	if !ok {
+		return store.Book{}, store.ErrNotFound
+	}
+
+	return book, nil
+}
+
+func (s *Store) GetAll(ctx context.Context) ([]store.Book, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	books := make([]store.Book, 0, len(s.books))
+	for _, book := range s.books {
+		books = append(books, book)
+	}
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+
+	return books, nil
+}
+
+func (s *Store) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.books, id)
+	return nil
+}
+
+func (s *Store) DistinctAuthors(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var authors []string
+	for _, book := range s.books {
+		if !seen[book.Author] {
+			seen[book.Author] = true
+			authors = append(authors, book.Author)
+		}
+	}
+
+	return authors, nil
+}
+
+func (s *Store) DistinctYears(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var years []string
+	for _, book := range s.books {
+		if !seen[book.Year] {
+			seen[book.Year] = true
+			years = append(years, book.Year)
+		}
+	}
+
+	return years, nil
+}
+
+// Search matches q against name/author case-insensitively and applies the
+// optional exact-match filters, mirroring mongostore's $text + filter
+// behavior closely enough for the HTTP layer to be tested against either
+// backend.
+func (s *Store) Search(ctx context.Context, q string, filters store.SearchFilters, page int, pageSize int) ([]store.Book, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	needle := strings.ToLower(q)
+	var matched []store.Book
+	for _, book := range s.books {
+		if needle != "" &&
+			!strings.Contains(strings.ToLower(book.Name), needle) &&
+			!strings.Contains(strings.ToLower(book.Author), needle) {
+			continue
+		}
+		if filters.Author != "" && book.Author != filters.Author {
+			continue
+		}
+		if filters.Year != "" && book.Year != filters.Year {
+			continue
+		}
+		if filters.Pages != "" && book.Pages != filters.Pages {
+			continue
+		}
+		matched = append(matched, book)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	total := int64(len(matched))
+	start := min((page-1)*pageSize, len(matched))
+	end := min(start+pageSize, len(matched))
+
+	return matched[start:end], total, nil
+}
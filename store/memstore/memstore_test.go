@@ -0,0 +1,131 @@
+package memstore
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/janthoXO/caps-ex1/store"
+)
+
+func TestCreateGetUpdateDelete(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(store.Config{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	book := store.Book{ID: "b1", Name: "The Vortex", Author: "José Eustasio Rivera", Year: "1924"}
+	if _, err := s.Create(ctx, book); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err := s.Create(ctx, book); err == nil {
+		t.Fatal("Create: expected error creating a duplicate id, got nil")
+	}
+
+	got, err := s.Get(ctx, "b1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got != book {
+		t.Fatalf("Get: got %+v, want %+v", got, book)
+	}
+
+	book.Year = "1925"
+	if _, err := s.Update(ctx, "b1", book); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got, _ := s.Get(ctx, "b1"); got.Year != "1925" {
+		t.Fatalf("Update: year not persisted, got %q", got.Year)
+	}
+
+	if _, err := s.Update(ctx, "missing", book); err != store.ErrNotFound {
+		t.Fatalf("Update: got err %v, want store.ErrNotFound", err)
+	}
+
+	if err := s.Delete(ctx, "b1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get(ctx, "b1"); err != store.ErrNotFound {
+		t.Fatalf("Get after Delete: got err %v, want store.ErrNotFound", err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	repo, err := New(store.Config{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	books := []store.Book{
+		{ID: "b1", Name: "The Vortex", Author: "José Eustasio Rivera", Year: "1924"},
+		{ID: "b2", Name: "Frankenstein", Author: "Mary Shelley", Year: "1818"},
+		{ID: "b3", Name: "The Black Cat", Author: "Edgar Allan Poe", Year: "1843"},
+	}
+	for _, b := range books {
+		if _, err := repo.Create(ctx, b); err != nil {
+			t.Fatalf("Create(%s): %v", b.ID, err)
+		}
+	}
+
+	results, total, err := repo.Search(ctx, "the", store.SearchFilters{}, 1, 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if total != 2 {
+		t.Fatalf("Search(%q): got total %d, want 2", "the", total)
+	}
+	for _, r := range results {
+		if r.ID != "b1" && r.ID != "b3" {
+			t.Fatalf("Search(%q): unexpected result %+v", "the", r)
+		}
+	}
+
+	results, total, err = repo.Search(ctx, "the", store.SearchFilters{Author: "Edgar Allan Poe"}, 1, 10)
+	if err != nil {
+		t.Fatalf("Search with filter: %v", err)
+	}
+	if total != 1 || len(results) != 1 || results[0].ID != "b3" {
+		t.Fatalf("Search with filter: got %+v (total %d), want only b3", results, total)
+	}
+}
+
+func TestCreateManyReportsDuplicatesWithoutFailingTheBatch(t *testing.T) {
+	ctx := context.Background()
+	repo, err := New(store.Config{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	bulkRepo, ok := repo.(store.BulkRepository)
+	if !ok {
+		t.Fatal("memstore.Store does not implement store.BulkRepository")
+	}
+
+	if _, err := repo.Create(ctx, store.Book{ID: "b1", Name: "Existing"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	results, err := bulkRepo.CreateMany(ctx, []store.Book{
+		{ID: "b1", Name: "Duplicate"},
+		{ID: "b2", Name: "New"},
+	}, 10)
+	if err != nil {
+		t.Fatalf("CreateMany: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("CreateMany: got %d results, want 2", len(results))
+	}
+	if results[0].Status != http.StatusConflict {
+		t.Fatalf("CreateMany: duplicate got status %d, want %d", results[0].Status, http.StatusConflict)
+	}
+	if results[1].Status != http.StatusCreated {
+		t.Fatalf("CreateMany: new book got status %d, want %d", results[1].Status, http.StatusCreated)
+	}
+	if _, err := repo.Get(ctx, "b2"); err != nil {
+		t.Fatalf("Get(b2) after CreateMany: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package mongostore
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/janthoXO/caps-ex1/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreateMany satisfies store.BulkRepository. Each batch is inserted
+// unordered so a duplicate in the middle of a batch doesn't block the rest
+// of it; duplicate-key failures are reported per book as 409s instead of
+// failing the whole import.
+func (s *Store) CreateMany(ctx context.Context, books []store.Book, batchSize int) ([]store.BulkResult, error) {
+	if batchSize < 1 {
+		batchSize = 100
+	}
+
+	results := make([]store.BulkResult, 0, len(books))
+	for start := 0; start < len(books); start += batchSize {
+		end := min(start+batchSize, len(books))
+		results = append(results, s.createBatch(ctx, books[start:end])...)
+	}
+
+	return results, nil
+}
+
+func (s *Store) createBatch(ctx context.Context, batch []store.Book) []store.BulkResult {
+	docs := make([]interface{}, len(batch))
+	for i, book := range batch {
+		docs[i] = fromBook(book)
+	}
+
+	_, err := s.coll.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		results := make([]store.BulkResult, len(batch))
+		for i, book := range batch {
+			results[i] = store.BulkResult{ID: book.ID, Status: http.StatusCreated}
+		}
+		return results
+	}
+
+	bwe, ok := err.(mongo.BulkWriteException)
+	if !ok {
+		results := make([]store.BulkResult, len(batch))
+		for i, book := range batch {
+			results[i] = store.BulkResult{ID: book.ID, Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+		return results
+	}
+
+	failures := make(map[int]mongo.BulkWriteError, len(bwe.WriteErrors))
+	for _, we := range bwe.WriteErrors {
+		failures[we.Index] = we
+	}
+
+	results := make([]store.BulkResult, len(batch))
+	for i, book := range batch {
+		we, failed := failures[i]
+		if !failed {
+			results[i] = store.BulkResult{ID: book.ID, Status: http.StatusCreated}
+			continue
+		}
+
+		status := http.StatusInternalServerError
+		msg := we.Error()
+		if mongo.IsDuplicateKeyError(we) {
+			status = http.StatusConflict
+			msg = "duplicate book"
+		}
+		results[i] = store.BulkResult{ID: book.ID, Status: status, Error: msg}
+	}
+
+	return results
+}
+
+// StreamAll satisfies store.BulkRepository, walking the collection with a
+// cursor so the caller never has to hold every book in memory at once.
+func (s *Store) StreamAll(ctx context.Context, fn func(store.Book) error) error {
+	cursor, err := s.coll.Find(ctx, bson.D{{}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc bookDoc
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		if err := fn(doc.toBook()); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
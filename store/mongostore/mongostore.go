@@ -0,0 +1,306 @@
+// Package mongostore implements store.Repository on top of a MongoDB
+// collection. Importing the package registers it under the name "mongo".
+package mongostore
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/janthoXO/caps-ex1/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	store.Register("mongo", New)
+}
+
+// bookDoc is the Mongo wire format for a book. It carries the internal
+// ObjectID alongside the application-assigned id.
+type bookDoc struct {
+	MongoID     primitive.ObjectID `bson:"_id,omitempty"`
+	ID          string             `bson:"id,omitempty"`
+	BookName    string             `bson:"bookname,omitempty"`
+	BookAuthor  string             `bson:"bookauthor,omitempty"`
+	BookEdition string             `bson:"bookedition,omitempty"`
+	BookPages   string             `bson:"bookpages,omitempty"`
+	BookYear    string             `bson:"bookyear,omitempty"`
+}
+
+func (d bookDoc) toBook() store.Book {
+	return store.Book{
+		ID:      d.ID,
+		Name:    d.BookName,
+		Author:  d.BookAuthor,
+		Edition: d.BookEdition,
+		Pages:   d.BookPages,
+		Year:    d.BookYear,
+	}
+}
+
+func fromBook(b store.Book) bookDoc {
+	return bookDoc{
+		ID:          b.ID,
+		BookName:    b.Name,
+		BookAuthor:  b.Author,
+		BookEdition: b.Edition,
+		BookPages:   b.Pages,
+		BookYear:    b.Year,
+	}
+}
+
+// Store is a store.Repository backed by a MongoDB collection.
+type Store struct {
+	client *mongo.Client
+	coll   *mongo.Collection
+}
+
+// New connects to MongoDB and prepares the books collection and its
+// indexes. It satisfies store.Factory and is registered as "mongo".
+func New(cfg store.Config) (store.Repository, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.DatabaseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	coll, err := prepareCollection(client, cfg.DatabaseName, cfg.Collection)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{client: client, coll: coll}, nil
+}
+
+// prepareCollection makes sure the collection and its indexes exist,
+// creating them the first time the backend connects.
+func prepareCollection(client *mongo.Client, dbName string, collecName string) (*mongo.Collection, error) {
+	db := client.Database(dbName)
+
+	names, err := db.ListCollectionNames(context.TODO(), bson.D{{}})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Collections in database %s: %v", dbName, names)
+	if !slices.Contains(names, collecName) {
+		cmd := bson.D{{Key: "create", Value: collecName}}
+		var result bson.M
+		if err = db.RunCommand(context.TODO(), cmd).Decode(&result); err != nil {
+			log.Fatal(err)
+			return nil, err
+		}
+	}
+
+	coll := db.Collection(collecName)
+
+	// Create a unique index on the "id" field
+	_, err = coll.Indexes().CreateOne(
+		context.TODO(),
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	)
+	if err != nil {
+		log.Fatal(err)
+		return nil, err
+	}
+
+	// Create a composite unique index on name, author, year and pages
+	_, err = coll.Indexes().CreateOne(
+		context.TODO(),
+		mongo.IndexModel{
+			Keys: bson.D{
+				{Key: "bookname", Value: 1},
+				{Key: "bookauthor", Value: 1},
+				{Key: "bookyear", Value: 1},
+				{Key: "bookpages", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	)
+	if err != nil {
+		log.Fatal(err)
+		return nil, err
+	}
+
+	// Create a text index over the searchable fields so we can run
+	// $text queries with relevance scoring from Search
+	_, err = coll.Indexes().CreateOne(
+		context.TODO(),
+		mongo.IndexModel{
+			Keys: bson.D{
+				{Key: "bookname", Value: "text"},
+				{Key: "bookauthor", Value: "text"},
+			},
+		},
+	)
+	if err != nil {
+		log.Fatal(err)
+		return nil, err
+	}
+
+	return coll, nil
+}
+
+// Close disconnects the underlying Mongo client. Callers that want a clean
+// shutdown can type-assert for it: `if c, ok := repo.(interface{ Close(context.Context) error }); ok { ... }`.
+func (s *Store) Close(ctx context.Context) error {
+	return s.client.Disconnect(ctx)
+}
+
+func (s *Store) Create(ctx context.Context, book store.Book) (store.Book, error) {
+	doc := fromBook(book)
+	if _, err := s.coll.InsertOne(ctx, doc); err != nil {
+		return store.Book{}, err
+	}
+	return book, nil
+}
+
+func (s *Store) Update(ctx context.Context, id string, book store.Book) (store.Book, error) {
+	book.ID = id
+	filter := bson.M{"id": id}
+	update := bson.M{"$set": fromBook(book)}
+
+	result, err := s.coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return store.Book{}, err
+	}
+
+	log.Debugf("result: %+v", result)
+	if result.MatchedCount == 0 {
+		return store.Book{}, store.ErrNotFound
+	}
+
+	return book, nil
+}
+
+func (s *Store) Get(ctx context.Context, id string) (store.Book, error) {
+	var doc bookDoc
+	err := s.coll.FindOne(ctx, bson.M{"id": id}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return store.Book{}, store.ErrNotFound
+	}
+	if err != nil {
+		return store.Book{}, err
+	}
+
+	return doc.toBook(), nil
+}
+
+func (s *Store) GetAll(ctx context.Context) ([]store.Book, error) {
+	cursor, err := s.coll.Find(ctx, bson.D{{}})
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []bookDoc
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	books := make([]store.Book, len(docs))
+	for i, doc := range docs {
+		books[i] = doc.toBook()
+	}
+
+	return books, nil
+}
+
+func (s *Store) Delete(ctx context.Context, id string) error {
+	_, err := s.coll.DeleteOne(ctx, bson.M{"id": id})
+	return err
+}
+
+func (s *Store) DistinctAuthors(ctx context.Context) (authors []string, err error) {
+	results, err := s.coll.Distinct(ctx, "bookauthor", bson.D{{}})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, res := range results {
+		authors = append(authors, res.(string))
+	}
+
+	return authors, nil
+}
+
+func (s *Store) DistinctYears(ctx context.Context) (years []string, err error) {
+	results, err := s.coll.Distinct(ctx, "bookyear", bson.D{{}})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, res := range results {
+		years = append(years, res.(string))
+	}
+
+	return years, nil
+}
+
+// Search runs a $text query against the collection, scored by textScore,
+// and applies the optional exact-match filters. Results are paginated with
+// page starting at 1; it also returns the total number of matches so
+// callers can render pagination controls.
+func (s *Store) Search(ctx context.Context, q string, filters store.SearchFilters, page int, pageSize int) ([]store.Book, int64, error) {
+	filter := bson.M{}
+	if q != "" {
+		filter["$text"] = bson.M{"$search": q}
+	}
+	if filters.Author != "" {
+		filter["bookauthor"] = filters.Author
+	}
+	if filters.Year != "" {
+		filter["bookyear"] = filters.Year
+	}
+	if filters.Pages != "" {
+		filter["bookpages"] = filters.Pages
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	total, err := s.coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	findOpts := options.Find().
+		SetSkip(int64((page - 1) * pageSize)).
+		SetLimit(int64(pageSize))
+
+	if q != "" {
+		// No SetProjection here: sorting by the $meta textScore doesn't
+		// require projecting it, and bookDoc has no field for the score.
+		findOpts.SetSort(bson.M{"score": bson.M{"$meta": "textScore"}})
+	}
+
+	cursor, err := s.coll.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var docs []bookDoc
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, 0, err
+	}
+
+	books := make([]store.Book, len(docs))
+	for i, doc := range docs {
+		books[i] = doc.toBook()
+	}
+
+	return books, total, nil
+}
@@ -0,0 +1,103 @@
+// Package store defines the persistence boundary between the HTTP layer and
+// whatever database backs it. Handlers only ever talk to a Repository, so a
+// backend can be swapped via the STORAGE_BACKEND env var (see New) without
+// touching a single route.
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is returned by Get/Update when no book matches the given id.
+var ErrNotFound = errors.New("store: book not found")
+
+// Book is the backend-agnostic representation of a book record. Backends
+// are responsible for mapping it to and from their own storage format. The
+// json tags match cmd's BookDTO field-for-field so a Book embedded directly
+// in a JSON response (e.g. eventlog's audit snapshots) has the same shape
+// as every other book returned by the API.
+type Book struct {
+	ID      string `json:"id"`
+	Name    string `json:"title"`
+	Author  string `json:"author"`
+	Edition string `json:"edition"`
+	Pages   string `json:"pages"`
+	Year    string `json:"year"`
+}
+
+// SearchFilters narrows a Search query down to exact matches on top of the
+// free-text query matched against name/author.
+type SearchFilters struct {
+	Author string
+	Year   string
+	Pages  string
+}
+
+// Repository is the set of operations every storage backend must provide.
+type Repository interface {
+	Create(ctx context.Context, book Book) (Book, error)
+	Update(ctx context.Context, id string, book Book) (Book, error)
+	Get(ctx context.Context, id string) (Book, error)
+	GetAll(ctx context.Context) ([]Book, error)
+	Delete(ctx context.Context, id string) error
+	DistinctAuthors(ctx context.Context) ([]string, error)
+	DistinctYears(ctx context.Context) ([]string, error)
+	Search(ctx context.Context, q string, filters SearchFilters, page int, pageSize int) ([]Book, int64, error)
+}
+
+// BulkResult reports the outcome of importing a single book via
+// BulkRepository.CreateMany, in the same order as the books given to it.
+type BulkResult struct {
+	ID     string
+	Status int
+	Error  string
+}
+
+// BulkRepository is an optional capability for backends that can
+// import/export books at scale without buffering everything in memory.
+// Callers should type-assert a Repository for it and fall back to
+// GetAll/Create (or reject the request) when it isn't supported.
+type BulkRepository interface {
+	// CreateMany inserts books in batches of batchSize, translating
+	// duplicate-key failures into per-book results instead of failing the
+	// whole batch.
+	CreateMany(ctx context.Context, books []Book, batchSize int) ([]BulkResult, error)
+	// StreamAll calls fn once per book without loading the full result set
+	// into memory, stopping at the first error either from the backend or
+	// from fn itself.
+	StreamAll(ctx context.Context, fn func(Book) error) error
+}
+
+// Config carries the settings a backend factory needs to construct a
+// Repository. Not every field is relevant to every backend.
+type Config struct {
+	DatabaseURL  string
+	DatabaseName string
+	Collection   string
+}
+
+// Factory builds a Repository from Config. Backends register one under a
+// name with Register.
+type Factory func(cfg Config) (Repository, error)
+
+var registry = map[string]Factory{}
+
+// Register makes a backend factory available under name. It is meant to be
+// called from a backend package's init() so importing it for side effects
+// (e.g. `_ "github.com/janthoXO/caps-ex1/store/mongostore"`) is enough to
+// make it selectable via STORAGE_BACKEND.
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+// New builds the Repository registered under name, or an error if no
+// backend with that name was registered.
+func New(name string, cfg Config) (Repository, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("store: unknown backend %q", name)
+	}
+	return factory(cfg)
+}